@@ -0,0 +1,195 @@
+package do
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"k8s.io/kubernetes/pkg/cloudprovider"
+
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/context"
+)
+
+// dropletCacheTTL bounds how long a dropletCache will serve a listing
+// before re-fetching it from the DO API. It is intentionally short: node
+// sets churn (scale up/down, rebuilds) and we'd rather pay for an extra API
+// call than hand back a stale droplet.
+const dropletCacheTTL = 30 * time.Second
+
+// dropletCache memoizes the droplets tagged for a cluster, keyed by both ID
+// and name, and refreshes the full (paginated) listing once the TTL has
+// passed or when a lookup misses. Concurrent callers that observe a stale or empty cache collapse
+// into a single in-flight refresh via singleflight, so a burst of
+// InstanceID/InstanceType/GetZone calls from the node controller doesn't
+// turn into a burst of List calls against the DO API.
+type dropletCache struct {
+	client    *godo.Client
+	clusterID string
+	ttl       time.Duration
+
+	group singleflight.Group
+
+	mu             sync.RWMutex
+	dropletsByID   map[int]*godo.Droplet
+	dropletsByName map[string]*godo.Droplet
+	fetchedAt      time.Time
+}
+
+func newDropletCache(client *godo.Client, clusterID string) *dropletCache {
+	return &dropletCache{
+		client:    client,
+		clusterID: clusterID,
+		ttl:       dropletCacheTTL,
+	}
+}
+
+// byName returns the droplet with the given name, refreshing the cache
+// first if it is stale or doesn't yet contain the name.
+func (c *dropletCache) byName(ctx context.Context, name string) (*godo.Droplet, error) {
+	if droplet, ok := c.lookup(func() (*godo.Droplet, bool) {
+		d, ok := c.byNameLocked(name)
+		return d, ok
+	}); ok {
+		return droplet, nil
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	droplet, ok := c.byNameLocked(name)
+	c.mu.RUnlock()
+	if !ok {
+		return nil, cloudprovider.InstanceNotFound
+	}
+	return droplet, nil
+}
+
+// byID returns the droplet with the given ID, refreshing the cache first
+// if it is stale or doesn't yet contain the ID.
+func (c *dropletCache) byID(ctx context.Context, id int) (*godo.Droplet, error) {
+	if droplet, ok := c.lookup(func() (*godo.Droplet, bool) {
+		d, ok := c.byIDLocked(id)
+		return d, ok
+	}); ok {
+		return droplet, nil
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	droplet, ok := c.byIDLocked(id)
+	c.mu.RUnlock()
+	if ok {
+		return droplet, nil
+	}
+
+	// The droplet may not carry the cluster tag (e.g. it's not part of
+	// this cluster) or may have been created after the last refresh.
+	// Fall back to a direct Get rather than reporting InstanceNotFound.
+	droplet, resp, err := c.client.Droplets.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DO API returned non-200 status code: %d", resp.StatusCode)
+	}
+	return droplet, nil
+}
+
+func (c *dropletCache) lookup(get func() (*godo.Droplet, bool)) (*godo.Droplet, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if time.Since(c.fetchedAt) > c.ttl {
+		return nil, false
+	}
+	return get()
+}
+
+func (c *dropletCache) byNameLocked(name string) (*godo.Droplet, bool) {
+	droplet, ok := c.dropletsByName[name]
+	return droplet, ok
+}
+
+func (c *dropletCache) byIDLocked(id int) (*godo.Droplet, bool) {
+	droplet, ok := c.dropletsByID[id]
+	return droplet, ok
+}
+
+// refresh re-lists every droplet tagged for this cluster, paginating
+// through all pages, and swaps the result into the cache. Concurrent
+// refreshes are collapsed into one another via singleflight.
+func (c *dropletCache) refresh(ctx context.Context) error {
+	_, err, _ := c.group.Do("refresh", func() (interface{}, error) {
+		droplets, err := c.listAllTagged(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		byID := make(map[int]*godo.Droplet, len(droplets))
+		byName := make(map[string]*godo.Droplet, len(droplets))
+		for i := range droplets {
+			d := &droplets[i]
+			byID[d.ID] = d
+			byName[d.Name] = d
+		}
+
+		c.mu.Lock()
+		c.dropletsByID = byID
+		c.dropletsByName = byName
+		c.fetchedAt = time.Now()
+		c.mu.Unlock()
+
+		return nil, nil
+	})
+	return err
+}
+
+// listAllTagged lists every droplet tagged for this cluster, following
+// pagination links until resp.Links reports the last page.
+func (c *dropletCache) listAllTagged(ctx context.Context) ([]godo.Droplet, error) {
+	var all []godo.Droplet
+
+	opt := &godo.ListOptions{Page: 1, PerPage: 200}
+	for {
+		droplets, resp, err := c.client.Droplets.ListByTag(ctx, buildClusterTag(c.clusterID), opt)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("DO API returned non-200 status code: %d", resp.StatusCode)
+		}
+
+		all = append(all, droplets...)
+
+		if resp.Links == nil || resp.Links.IsLastPage() {
+			break
+		}
+
+		page, err := resp.Links.CurrentPage()
+		if err != nil {
+			return nil, fmt.Errorf("error getting current page from droplet listing: %v", err)
+		}
+		opt.Page = page + 1
+	}
+
+	return all, nil
+}
+
+// dropletIDFromProviderID converts a providerID/instance ID string into the
+// int form the godo API expects.
+func dropletIDFromProviderID(providerID string) (int, error) {
+	id, err := strconv.Atoi(providerID)
+	if err != nil {
+		return 0, fmt.Errorf("error converting droplet id to int: %v", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,156 @@
+package do
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/kubernetes/pkg/cloudprovider"
+
+	"github.com/digitalocean/godo"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// ProviderName is the name of the DigitalOcean provider.
+	ProviderName = "digitalocean"
+
+	// doAccessTokenEnv is the environment variable that holds the DO API
+	// access token used to authenticate the godo client.
+	doAccessTokenEnv = "DIGITALOCEAN_ACCESS_TOKEN"
+)
+
+func getDOToken() string {
+	return os.Getenv(doAccessTokenEnv)
+}
+
+// Config is the configuration passed to the DO provider via the
+// --cloud-config flag on the CCM binary.
+type Config struct {
+	Spec struct {
+		// ClusterID identifies the Kubernetes cluster this CCM instance is
+		// managing. It is used to scope API lookups (e.g. droplet listings)
+		// to resources tagged for this cluster, see TagNameClusterID.
+		ClusterID string `yaml:"clusterID"`
+
+		// IPv6 enables reporting of droplets' public/private IPv6
+		// addresses as additional NodeAddresses. It defaults to false so
+		// existing single-stack clusters aren't surprised by new
+		// addresses appearing on their nodes.
+		IPv6 bool `yaml:"ipv6"`
+	} `yaml:"spec"`
+}
+
+// Cloud is an implementation of cloudprovider.Interface for DigitalOcean.
+type Cloud struct {
+	client    *godo.Client
+	clusterID string
+
+	instances cloudprovider.Instances
+	zones     cloudprovider.Zones
+}
+
+type tokenSource struct {
+	AccessToken string
+}
+
+func (t *tokenSource) Token() (*oauth2.Token, error) {
+	token := &oauth2.Token{
+		AccessToken: t.AccessToken,
+	}
+	return token, nil
+}
+
+func init() {
+	cloudprovider.RegisterCloudProvider(ProviderName, func(config io.Reader) (cloudprovider.Interface, error) {
+		return newCloud(config)
+	})
+}
+
+func newCloud(config io.Reader) (cloudprovider.Interface, error) {
+	token := getDOToken()
+	if token == "" {
+		return nil, fmt.Errorf("environment variable %q is required", doAccessTokenEnv)
+	}
+
+	var cfg Config
+	if config != nil {
+		contents, err := ioutil.ReadAll(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cloud config: %v", err)
+		}
+		if err := yaml.Unmarshal(contents, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse cloud config: %v", err)
+		}
+	}
+
+	if cfg.Spec.ClusterID == "" {
+		return nil, fmt.Errorf("cloud config is missing required field %q", "spec.clusterID")
+	}
+
+	tokenSource := &tokenSource{AccessToken: token}
+	oauthClient := oauth2.NewClient(oauth2.NoContext, tokenSource)
+	oauthClient.Transport = newRateLimitedTransport(oauthClient.Transport)
+	client := godo.NewClient(oauthClient)
+
+	// instances and zones both look up droplets by tag/ID/name; share one
+	// dropletCache between them so a burst of calls across both interfaces
+	// collapses into a single refresh instead of each keeping its own.
+	cache := newDropletCache(client, cfg.Spec.ClusterID)
+
+	return &Cloud{
+		client:    client,
+		clusterID: cfg.Spec.ClusterID,
+		instances: newInstances(cache, cfg.Spec.IPv6),
+		zones:     newZones(cache),
+	}, nil
+}
+
+// Initialize provides the cloud with a kubernetes client builder and may
+// spawn goroutines to perform housekeeping activities within the cloud
+// provider.
+func (c *Cloud) Initialize(clientBuilder cloudprovider.ControllerClientBuilder, stop <-chan struct{}) {
+}
+
+// LoadBalancer returns a balancer interface. Also returns true if the
+// interface is supported, false otherwise.
+func (c *Cloud) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
+	return nil, false
+}
+
+// Instances returns an instances interface. Also returns true if the
+// interface is supported, false otherwise.
+func (c *Cloud) Instances() (cloudprovider.Instances, bool) {
+	return c.instances, true
+}
+
+// Zones returns a zones interface. Also returns true if the interface is
+// supported, false otherwise.
+func (c *Cloud) Zones() (cloudprovider.Zones, bool) {
+	return c.zones, true
+}
+
+// Clusters returns a clusters interface. Also returns true if the interface
+// is supported, false otherwise.
+func (c *Cloud) Clusters() (cloudprovider.Clusters, bool) {
+	return nil, false
+}
+
+// Routes returns a routes interface along with whether the interface is
+// supported.
+func (c *Cloud) Routes() (cloudprovider.Routes, bool) {
+	return nil, false
+}
+
+// ProviderName returns the cloud provider ID.
+func (c *Cloud) ProviderName() string {
+	return ProviderName
+}
+
+// HasClusterID returns true if the cluster has a clusterID
+func (c *Cloud) HasClusterID() bool {
+	return len(c.clusterID) > 0
+}
@@ -1,52 +1,105 @@
 package do
 
 import (
-	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strconv"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 
+	"github.com/digitalocean/digitalocean-cloud-controller-manager/do/metadata"
 	"github.com/digitalocean/godo"
 	"github.com/digitalocean/godo/context"
 )
 
-const dropletIDMetadataURL = "http://169.254.169.254/metadata/v1/id"
+// TagNameClusterID is the prefix used to build the tag applied to every
+// droplet belonging to a given Kubernetes cluster, mirroring the tagging
+// conventions used by the DigitalOcean Terraform provider and kops
+// (TagKubernetesCluster, TagKubernetesInstanceGroup). The full tag is
+// built by buildClusterTag.
+const TagNameClusterID = "k8s"
+
+// buildClusterTag returns the droplet tag used to scope API lookups (e.g.
+// droplet listings) to the droplets belonging to clusterID.
+func buildClusterTag(clusterID string) string {
+	return fmt.Sprintf("%s:%s", TagNameClusterID, clusterID)
+}
 
 // instances Implements cloudprovider.Instances
 type instances struct {
-	client *godo.Client
+	cache    *dropletCache
+	metadata metadata.Client
+	ipv6     bool
 }
 
-func newInstances(client *godo.Client) cloudprovider.Instances {
-	return &instances{client}
+func newInstances(cache *dropletCache, ipv6 bool) cloudprovider.Instances {
+	return &instances{cache, metadata.NewClient(), ipv6}
 }
 
-// NodeAddresses returns all the valid addresses of the specified node
-// For DO, this is the public/private ipv4 addresses only for now
-// This method only fetches the addresses of the calling instances,
+// NodeAddresses returns all the valid addresses of the specified node.
+// For DO, this is the public/private ipv4 (and, if enabled, ipv6) addresses.
 func (i *instances) NodeAddresses(name types.NodeName) ([]v1.NodeAddress, error) {
-	selfDropletID, err := dropletID()
+	md, err := i.metadata.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fast path: if we're being asked for our own addresses, the droplet
+	// metadata service already has everything we need, so skip the DO API
+	// entirely. This mirrors the CloudStack provider's metadata-first
+	// design and keeps the metadata service (rather than the DO API) on
+	// the kubelet's hot path.
+	if md.Hostname == string(name) {
+		return addressesFromMetadata(md, i.ipv6), nil
+	}
+
+	droplet, err := i.dropletByName(context.TODO(), name)
 	if err != nil {
 		return nil, err
 	}
+	return i.addressesForDroplet(droplet)
+}
+
+// addressesFromMetadata builds the NodeAddress list for the local droplet
+// directly from its metadata document, without an API call.
+func addressesFromMetadata(md *metadata.Metadata, ipv6 bool) []v1.NodeAddress {
+	addresses := []v1.NodeAddress{{Type: v1.NodeHostName, Address: md.Hostname}}
+
+	if privateIP := md.PrivateIPv4(); privateIP != "" {
+		addresses = append(addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: privateIP})
+	}
+	if publicIP := md.PublicIPv4(); publicIP != "" {
+		addresses = append(addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: publicIP})
+	}
+
+	if ipv6 {
+		if privateIP := md.PrivateIPv6(); privateIP != "" {
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: privateIP})
+		}
+		if publicIP := md.PublicIPv6(); publicIP != "" {
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: publicIP})
+		}
+	}
 
-	return i.NodeAddressesByProviderID(selfDropletID)
+	return addresses
 }
 
 // NodeAddressesByProviderID returns all the valid addresses of the specified
 // node by providerId. For DO this is the public/private ipv4 addresses for now.
 func (i *instances) NodeAddressesByProviderID(providerId string) ([]v1.NodeAddress, error) {
-	// we can technically get all the required data from metadata service
 	droplet, err := i.dropletById(context.TODO(), providerId)
 	if err != nil {
 		return nil, err
 	}
 
+	return i.addressesForDroplet(droplet)
+}
+
+// addressesForDroplet builds the NodeAddress list for an arbitrary droplet
+// fetched from the DO API, as opposed to addressesFromMetadata which builds
+// it for the local droplet straight from its metadata document.
+func (i *instances) addressesForDroplet(droplet *godo.Droplet) ([]v1.NodeAddress, error) {
 	var addresses []v1.NodeAddress
 	addresses = append(addresses, v1.NodeAddress{Type: v1.NodeHostName, Address: droplet.Name})
 
@@ -62,9 +115,46 @@ func (i *instances) NodeAddressesByProviderID(providerId string) ([]v1.NodeAddre
 	}
 	addresses = append(addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: publicIP})
 
+	if i.ipv6 {
+		if privateIPv6 := privateIPv6(droplet); privateIPv6 != "" {
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: privateIPv6})
+		}
+		if publicIPv6 := publicIPv6(droplet); publicIPv6 != "" {
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: publicIPv6})
+		}
+	}
+
 	return addresses, nil
 }
 
+// publicIPv6 returns the droplet's public IPv6 address, if it has one.
+// DigitalOcean droplets only ever have a single public IPv6 address, unlike
+// IPv4 where a droplet may have multiple (e.g. a floating IP).
+func publicIPv6(droplet *godo.Droplet) string {
+	if droplet.Networks == nil {
+		return ""
+	}
+	for _, v6 := range droplet.Networks.V6 {
+		if v6.Type == "public" {
+			return v6.IPAddress
+		}
+	}
+	return ""
+}
+
+// privateIPv6 returns the droplet's private IPv6 address, if it has one.
+func privateIPv6(droplet *godo.Droplet) string {
+	if droplet.Networks == nil {
+		return ""
+	}
+	for _, v6 := range droplet.Networks.V6 {
+		if v6.Type == "private" {
+			return v6.IPAddress
+		}
+	}
+	return ""
+}
+
 // ExternalID returns the cloud provider ID of the node with the specified NodeName.
 // Note that if the instance does not exist or is no longer running, we must return ("", cloudprovider.InstanceNotFound)
 func (i *instances) ExternalID(nodeName types.NodeName) (string, error) {
@@ -101,86 +191,28 @@ func (i *instances) InstanceTypeByProviderID(providerId string) (string, error)
 	return droplet.SizeSlug, err
 }
 
-// AddSSHKeyToAllInstances adds an SSH public key as a legal identity for all instances
-// expected format for the key is standard ssh-keygen format: <protocol> <blob>
-func (i *instances) AddSSHKeyToAllInstances(user string, keyData []byte) error {
-	return errors.New("not implemented yet")
-}
-
 // CurrentNodeName returns the name of the node we are currently running on
 // On most clouds (e.g. GCE) this is the hostname, so we provide the hostname
 func (i *instances) CurrentNodeName(hostname string) (types.NodeName, error) {
 	return types.NodeName(hostname), nil
 }
 
-// dropletById returns the godo Droplet type corresponding to the provided id
+// dropletById returns the godo Droplet type corresponding to the provided id,
+// served from the shared dropletCache where possible.
 func (i *instances) dropletById(ctx context.Context, id string) (*godo.Droplet, error) {
-	intId, err := strconv.Atoi(id)
-	if err != nil {
-		return nil, fmt.Errorf("error converting droplet id to string: %v", err)
-	}
-
-	droplet, resp, err := i.client.Droplets.Get(ctx, intId)
+	intId, err := dropletIDFromProviderID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("DO API returned non-200 status code: %d", resp.StatusCode)
-	}
-
-	return droplet, nil
+	return i.cache.byID(ctx, intId)
 }
 
-// dropletByName returns the godo Droplet type corresponding to the node name
-// since we can only get droplets by id, we do a list of all droplets and return
-// the first one that matches the provided name
+// dropletByName returns the godo Droplet type corresponding to the node
+// name, served from the shared dropletCache where possible. Cache misses
+// are scoped to droplets tagged for this cluster (see buildClusterTag)
+// rather than scanning every droplet on the account, so this stays cheap as
+// unrelated droplets accumulate.
 func (i *instances) dropletByName(ctx context.Context, nodeName types.NodeName) (*godo.Droplet, error) {
-	// TODO (andrewsykim): list by tag once a tagging format is determined
-	droplets, resp, err := i.client.Droplets.List(ctx, &godo.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("DO API returned non-200 status code: %d", resp.StatusCode)
-	}
-
-	for _, droplet := range droplets {
-		if droplet.Name == string(nodeName) {
-			return &droplet, nil
-		}
-	}
-
-	return nil, cloudprovider.InstanceNotFound
-}
-
-// dropletID returns the currently running droplet id
-// using the metadata service available on all running droplets
-func dropletID() (string, error) {
-	return httpGet(dropletIDMetadataURL)
-}
-
-// httpGet is a convienance function to do an http GET on a provided url
-// and return the string version of the response body.
-// In this package it is used for retrieving droplet metadata
-//     e.g. http://169.254.169.254/metadata/v1/id"
-func httpGet(url string) (string, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", err
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("droplet metadata returned non-200 status code: %d", resp.StatusCode)
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	return string(bodyBytes), nil
+	return i.cache.byName(ctx, string(nodeName))
 }
@@ -0,0 +1,137 @@
+package do
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/digitalocean/digitalocean-cloud-controller-manager/do/metadata"
+	"github.com/digitalocean/godo"
+)
+
+// fakeMetadataClient implements metadata.Client with a canned response, so
+// tests can exercise the metadata-first code paths without a real droplet
+// metadata service.
+type fakeMetadataClient struct {
+	md  *metadata.Metadata
+	err error
+}
+
+func (f *fakeMetadataClient) Metadata() (*metadata.Metadata, error) {
+	return f.md, f.err
+}
+
+func TestAddressesFromMetadata(t *testing.T) {
+	md := &metadata.Metadata{
+		Hostname: "node-1",
+		Interfaces: metadata.Interfaces{
+			Public:  []metadata.Interface{{IPv4: &metadata.AddressConfig{IPAddress: "203.0.113.1"}, IPv6: &metadata.AddressConfig{IPAddress: "2001:db8::1"}}},
+			Private: []metadata.Interface{{IPv4: &metadata.AddressConfig{IPAddress: "10.0.0.1"}, IPv6: &metadata.AddressConfig{IPAddress: "fd00::1"}}},
+		},
+	}
+
+	got := addressesFromMetadata(md, false)
+	want := []v1.NodeAddress{
+		{Type: v1.NodeHostName, Address: "node-1"},
+		{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		{Type: v1.NodeExternalIP, Address: "203.0.113.1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("addressesFromMetadata(ipv6=false) = %+v, want %+v", got, want)
+	}
+
+	got = addressesFromMetadata(md, true)
+	want = append(want,
+		v1.NodeAddress{Type: v1.NodeInternalIP, Address: "fd00::1"},
+		v1.NodeAddress{Type: v1.NodeExternalIP, Address: "2001:db8::1"},
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("addressesFromMetadata(ipv6=true) = %+v, want %+v", got, want)
+	}
+}
+
+func TestNodeAddressesFastPath(t *testing.T) {
+	md := &metadata.Metadata{
+		Hostname: "self",
+		Interfaces: metadata.Interfaces{
+			Private: []metadata.Interface{{IPv4: &metadata.AddressConfig{IPAddress: "10.0.0.5"}}},
+			Public:  []metadata.Interface{{IPv4: &metadata.AddressConfig{IPAddress: "198.51.100.5"}}},
+		},
+	}
+
+	i := &instances{metadata: &fakeMetadataClient{md: md}}
+
+	addrs, err := i.NodeAddresses(types.NodeName("self"))
+	if err != nil {
+		t.Fatalf("NodeAddresses() returned error: %v", err)
+	}
+
+	want := []v1.NodeAddress{
+		{Type: v1.NodeHostName, Address: "self"},
+		{Type: v1.NodeInternalIP, Address: "10.0.0.5"},
+		{Type: v1.NodeExternalIP, Address: "198.51.100.5"},
+	}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Errorf("NodeAddresses() = %+v, want %+v", addrs, want)
+	}
+}
+
+func TestPublicPrivateIPv6(t *testing.T) {
+	droplet := &godo.Droplet{
+		Networks: &godo.Networks{
+			V6: []godo.NetworkV6{
+				{IPAddress: "2001:db8::1", Type: "public"},
+				{IPAddress: "fd00::1", Type: "private"},
+			},
+		},
+	}
+
+	if got := publicIPv6(droplet); got != "2001:db8::1" {
+		t.Errorf("publicIPv6() = %q, want %q", got, "2001:db8::1")
+	}
+	if got := privateIPv6(droplet); got != "fd00::1" {
+		t.Errorf("privateIPv6() = %q, want %q", got, "fd00::1")
+	}
+
+	empty := &godo.Droplet{Networks: &godo.Networks{}}
+	if got := publicIPv6(empty); got != "" {
+		t.Errorf("publicIPv6() on droplet with no v6 networks = %q, want empty", got)
+	}
+
+	nilNetworks := &godo.Droplet{}
+	if got := publicIPv6(nilNetworks); got != "" {
+		t.Errorf("publicIPv6() on droplet with nil Networks = %q, want empty", got)
+	}
+	if got := privateIPv6(nilNetworks); got != "" {
+		t.Errorf("privateIPv6() on droplet with nil Networks = %q, want empty", got)
+	}
+}
+
+func TestAddressesForDroplet(t *testing.T) {
+	droplet := &godo.Droplet{
+		Name: "other-node",
+		Networks: &godo.Networks{
+			V4: []godo.NetworkV4{
+				{IPAddress: "10.0.0.9", Type: "private"},
+				{IPAddress: "198.51.100.9", Type: "public"},
+			},
+		},
+	}
+
+	i := &instances{}
+	addrs, err := i.addressesForDroplet(droplet)
+	if err != nil {
+		t.Fatalf("addressesForDroplet() returned error: %v", err)
+	}
+
+	want := []v1.NodeAddress{
+		{Type: v1.NodeHostName, Address: "other-node"},
+		{Type: v1.NodeInternalIP, Address: "10.0.0.9"},
+		{Type: v1.NodeExternalIP, Address: "198.51.100.9"},
+	}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Errorf("addressesForDroplet() = %+v, want %+v", addrs, want)
+	}
+}
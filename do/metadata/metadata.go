@@ -0,0 +1,134 @@
+// Package metadata provides a client for the DigitalOcean droplet metadata
+// service (http://169.254.169.254/metadata/v1.json), available to every
+// running droplet without an authenticated API call. See
+// https://developers.digitalocean.com/documentation/metadata/ for the
+// response schema.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// baseURL is the address of the metadata service, reachable from inside any
+// running droplet.
+const baseURL = "http://169.254.169.254"
+
+// Metadata is the subset of the droplet metadata document this provider
+// cares about.
+type Metadata struct {
+	DropletID  int        `json:"droplet_id"`
+	Hostname   string     `json:"hostname"`
+	Region     string     `json:"region"`
+	Tags       []string   `json:"tags"`
+	UserData   string     `json:"user_data"`
+	Interfaces Interfaces `json:"interfaces"`
+}
+
+// Interfaces groups the public and private network interfaces attached to
+// the droplet.
+type Interfaces struct {
+	Public  []Interface `json:"public"`
+	Private []Interface `json:"private"`
+}
+
+// Interface describes a single network interface.
+type Interface struct {
+	IPv4 *AddressConfig `json:"ipv4,omitempty"`
+	IPv6 *AddressConfig `json:"ipv6,omitempty"`
+	MAC  string         `json:"mac"`
+	Type string         `json:"type"`
+}
+
+// AddressConfig is the address configuration of one IP family on an
+// interface.
+type AddressConfig struct {
+	IPAddress string `json:"ip_address"`
+	Netmask   string `json:"netmask"`
+	Gateway   string `json:"gateway"`
+}
+
+// Client fetches the metadata document describing the droplet it is run
+// from. It is an interface so callers can substitute a fake metadata
+// server in tests rather than depending on 169.254.169.254 being reachable.
+type Client interface {
+	Metadata() (*Metadata, error)
+}
+
+type client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client that queries the real droplet metadata
+// service.
+func NewClient() Client {
+	return &client{baseURL: baseURL, httpClient: http.DefaultClient}
+}
+
+// Metadata fetches and parses the droplet's /metadata/v1.json document.
+func (c *client) Metadata() (*Metadata, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/metadata/v1.json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("droplet metadata returned non-200 status code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var md Metadata
+	if err := json.Unmarshal(body, &md); err != nil {
+		return nil, fmt.Errorf("error parsing droplet metadata: %v", err)
+	}
+
+	return &md, nil
+}
+
+// PublicIPv4 returns the first public IPv4 address on the droplet, if any.
+func (m *Metadata) PublicIPv4() string {
+	return firstIPv4(m.Interfaces.Public)
+}
+
+// PrivateIPv4 returns the first private IPv4 address on the droplet, if
+// any.
+func (m *Metadata) PrivateIPv4() string {
+	return firstIPv4(m.Interfaces.Private)
+}
+
+// PublicIPv6 returns the first public IPv6 address on the droplet, if any.
+func (m *Metadata) PublicIPv6() string {
+	return firstIPv6(m.Interfaces.Public)
+}
+
+// PrivateIPv6 returns the first private IPv6 address on the droplet, if
+// any.
+func (m *Metadata) PrivateIPv6() string {
+	return firstIPv6(m.Interfaces.Private)
+}
+
+func firstIPv4(interfaces []Interface) string {
+	for _, iface := range interfaces {
+		if iface.IPv4 != nil && iface.IPv4.IPAddress != "" {
+			return iface.IPv4.IPAddress
+		}
+	}
+	return ""
+}
+
+func firstIPv6(interfaces []Interface) string {
+	for _, iface := range interfaces {
+		if iface.IPv6 != nil && iface.IPv6.IPAddress != "" {
+			return iface.IPv6.IPAddress
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testDoc = `{
+	"droplet_id": 12345,
+	"hostname": "node-1",
+	"region": "nyc1",
+	"interfaces": {
+		"public": [{"ipv4": {"ip_address": "203.0.113.1"}, "ipv6": {"ip_address": "2001:db8::1"}, "type": "public"}],
+		"private": [{"ipv4": {"ip_address": "10.0.0.1"}, "ipv6": {"ip_address": "fd00::1"}, "type": "private"}]
+	}
+}`
+
+func TestClientMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata/v1.json" {
+			t.Errorf("requested path = %q, want %q", r.URL.Path, "/metadata/v1.json")
+		}
+		w.Write([]byte(testDoc))
+	}))
+	defer server.Close()
+
+	c := &client{baseURL: server.URL, httpClient: server.Client()}
+
+	md, err := c.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() returned error: %v", err)
+	}
+
+	if md.DropletID != 12345 {
+		t.Errorf("DropletID = %d, want 12345", md.DropletID)
+	}
+	if md.Hostname != "node-1" {
+		t.Errorf("Hostname = %q, want %q", md.Hostname, "node-1")
+	}
+	if got := md.PublicIPv4(); got != "203.0.113.1" {
+		t.Errorf("PublicIPv4() = %q, want %q", got, "203.0.113.1")
+	}
+	if got := md.PrivateIPv4(); got != "10.0.0.1" {
+		t.Errorf("PrivateIPv4() = %q, want %q", got, "10.0.0.1")
+	}
+	if got := md.PublicIPv6(); got != "2001:db8::1" {
+		t.Errorf("PublicIPv6() = %q, want %q", got, "2001:db8::1")
+	}
+	if got := md.PrivateIPv6(); got != "fd00::1" {
+		t.Errorf("PrivateIPv6() = %q, want %q", got, "fd00::1")
+	}
+}
+
+func TestClientMetadataNon200(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := &client{baseURL: server.URL, httpClient: server.Client()}
+
+	if _, err := c.Metadata(); err == nil {
+		t.Error("Metadata() returned no error for a non-200 response")
+	}
+}
+
+func TestMetadataNoAddresses(t *testing.T) {
+	md := &Metadata{}
+
+	for name, got := range map[string]string{
+		"PublicIPv4":  md.PublicIPv4(),
+		"PrivateIPv4": md.PrivateIPv4(),
+		"PublicIPv6":  md.PublicIPv6(),
+		"PrivateIPv6": md.PrivateIPv6(),
+	} {
+		if got != "" {
+			t.Errorf("%s() = %q, want empty", name, got)
+		}
+	}
+}
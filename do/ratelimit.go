@@ -0,0 +1,100 @@
+package do
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimitHeaderRemaining/Reset are the DO API headers used to track the
+// remaining request budget for the current window, see
+// https://developers.digitalocean.com/documentation/v2/#rate-limit
+const (
+	rateLimitHeaderRemaining = "RateLimit-Remaining"
+	rateLimitHeaderReset     = "RateLimit-Reset"
+
+	// rateLimitLowWatermark is the remaining-request count at or below which
+	// the transport starts backing off proactively instead of waiting to be
+	// rate limited outright.
+	rateLimitLowWatermark = 5
+
+	// rateLimitMaxBackoff bounds the wait RoundTrip derives from
+	// RateLimit-Reset; jitter of up to a tenth of that wait is added on top.
+	// RateLimit-Reset marks the end of the DO API's hourly window, so
+	// without a cap a response received early in the window could mean
+	// sleeping for most of an hour.
+	rateLimitMaxBackoff = 30 * time.Second
+)
+
+// rateLimitedTransport is an http.RoundTripper that inspects the DO API's
+// RateLimit-Remaining/RateLimit-Reset response headers and, once the
+// remaining budget runs low, sleeps (with jitter, to avoid every client
+// in a churning node set waking up and retrying in lockstep) until the
+// window resets, or for at most rateLimitMaxBackoff plus jitter. This keeps a large or rapidly scaling node set from
+// exhausting the account's API rate limit.
+type rateLimitedTransport struct {
+	next http.RoundTripper
+}
+
+func newRateLimitedTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &rateLimitedTransport{next: next}
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if d, ok := backoffDuration(resp.Header); ok {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+		}
+	}
+
+	return resp, nil
+}
+
+// backoffDuration returns how long to sleep before the next request, and
+// whether a sleep is warranted at all, based on the rate limit headers of
+// a response we just received.
+func backoffDuration(header http.Header) (time.Duration, bool) {
+	remaining, err := strconv.Atoi(header.Get(rateLimitHeaderRemaining))
+	if err != nil || remaining > rateLimitLowWatermark {
+		return 0, false
+	}
+
+	reset, err := strconv.ParseInt(header.Get(rateLimitHeaderReset), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := time.Until(time.Unix(reset, 0))
+	if wait <= 0 {
+		return 0, false
+	}
+	if wait > rateLimitMaxBackoff {
+		wait = rateLimitMaxBackoff
+	}
+
+	return wait + jitter(wait), true
+}
+
+// jitter returns a random duration in [0, wait/10), so concurrent callers
+// don't all wake up and retry in the same instant. rand.Int63n panics on
+// n <= 0, which wait/10 would be for any wait under 10ns, so it returns 0
+// rather than jittering in that case.
+func jitter(wait time.Duration) time.Duration {
+	n := int64(wait) / 10
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(n))
+}
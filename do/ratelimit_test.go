@@ -0,0 +1,108 @@
+package do
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBackoffDurationMissingHeaders(t *testing.T) {
+	if _, ok := backoffDuration(http.Header{}); ok {
+		t.Error("backoffDuration() = ok with no rate limit headers present")
+	}
+}
+
+func TestBackoffDurationAboveWatermark(t *testing.T) {
+	header := http.Header{}
+	header.Set(rateLimitHeaderRemaining, strconv.Itoa(rateLimitLowWatermark+1))
+	header.Set(rateLimitHeaderReset, strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+
+	if _, ok := backoffDuration(header); ok {
+		t.Error("backoffDuration() = ok with remaining above the low watermark")
+	}
+}
+
+func TestBackoffDurationLowRemaining(t *testing.T) {
+	header := http.Header{}
+	header.Set(rateLimitHeaderRemaining, "1")
+	header.Set(rateLimitHeaderReset, strconv.FormatInt(time.Now().Add(2*time.Second).Unix(), 10))
+
+	d, ok := backoffDuration(header)
+	if !ok {
+		t.Fatal("backoffDuration() = !ok with remaining below the low watermark")
+	}
+	if d <= 0 {
+		t.Errorf("backoffDuration() = %v, want a positive wait", d)
+	}
+}
+
+// jitter must never panic, including for the sub-10ns waits that make
+// wait/10 zero.
+func TestJitterDoesNotPanicOnTinyWait(t *testing.T) {
+	for _, wait := range []time.Duration{0, 1, 5, 9, 10, 99, time.Second} {
+		jitter(wait)
+	}
+}
+
+func TestJitterBound(t *testing.T) {
+	wait := time.Second
+	for i := 0; i < 100; i++ {
+		if j := jitter(wait); j < 0 || j >= wait/10 {
+			t.Fatalf("jitter(%v) = %v, want in [0, %v)", wait, j, wait/10)
+		}
+	}
+}
+
+func TestBackoffDurationCapsLongWait(t *testing.T) {
+	header := http.Header{}
+	header.Set(rateLimitHeaderRemaining, "1")
+	header.Set(rateLimitHeaderReset, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+
+	d, ok := backoffDuration(header)
+	if !ok {
+		t.Fatal("backoffDuration() = !ok with remaining below the low watermark")
+	}
+	if max := rateLimitMaxBackoff + rateLimitMaxBackoff/10; d > max {
+		t.Errorf("backoffDuration() = %v, want capped at roughly %v", d, rateLimitMaxBackoff)
+	}
+}
+
+// fakeRoundTripper returns a fixed response carrying the given headers.
+type fakeRoundTripper struct {
+	header http.Header
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Header: f.header, Body: http.NoBody}, nil
+}
+
+func TestRoundTripReturnsOnContextCancellation(t *testing.T) {
+	header := http.Header{}
+	header.Set(rateLimitHeaderRemaining, "1")
+	header.Set(rateLimitHeaderReset, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+
+	transport := newRateLimitedTransport(&fakeRoundTripper{header: header})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() returned error: %v", err)
+	}
+	req = req.WithContext(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		transport.RoundTrip(req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RoundTrip() did not return promptly after its context was canceled")
+	}
+}
@@ -0,0 +1,77 @@
+package do
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/context"
+)
+
+// AddSSHKeyToAllInstances registers an SSH public key with the DO account
+// expected format for the key is standard ssh-keygen format: <protocol> <blob>
+//
+// DO does not inject keys into already-running droplets, so this registers
+// the key on the account's SSH key list (the same list "doctl compute
+// ssh-key" manages); any droplet rebuilt or recreated against this account
+// picks it up from there.
+//
+// It does not add the key to droplets that are already running, and it does
+// not annotate nodes; the account-level key is the only change it makes.
+func (i *instances) AddSSHKeyToAllInstances(user string, keyData []byte) error {
+	_, err := ensureSSHKey(context.TODO(), i.cache.client, keyData)
+	return err
+}
+
+// ensureSSHKey registers keyData as an account-level SSH key if it isn't
+// already present, keyed by its fingerprint so repeated calls with the same
+// key are a no-op rather than creating duplicates.
+func ensureSSHKey(ctx context.Context, client *godo.Client, keyData []byte) (*godo.Key, error) {
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(keyData)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ssh key: %v", err)
+	}
+
+	fingerprint := md5Fingerprint(pubKey)
+
+	key, resp, err := client.Keys.GetByFingerprint(ctx, fingerprint)
+	if err == nil {
+		return key, nil
+	}
+	// Anything other than "no key with this fingerprint yet" (including a
+	// rate limit error) is returned as-is rather than masked, so callers
+	// can tell a godo.ErrorResponse/rate-limit error apart from a genuine
+	// not-found.
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	key, _, err = client.Keys.Create(ctx, &godo.KeyCreateRequest{
+		Name:      fingerprint,
+		PublicKey: string(keyData),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// md5Fingerprint returns the colon-separated hex MD5 fingerprint of pubKey,
+// in the same format as `ssh-keygen -l -E md5` and the DO API's
+// Key.Fingerprint field.
+func md5Fingerprint(pubKey ssh.PublicKey) string {
+	sum := md5.Sum(pubKey.Marshal())
+
+	hexPairs := make([]string, len(sum))
+	for i, b := range sum {
+		hexPairs[i] = hex.EncodeToString([]byte{b})
+	}
+
+	return strings.Join(hexPairs, ":")
+}
@@ -0,0 +1,26 @@
+package do
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testAuthorizedKey and testKeyMD5Fingerprint are a matched ssh-keygen
+// generated key pair, used to check md5Fingerprint against a known-good
+// fingerprint (cross-checked with `ssh-keygen -l -E md5`).
+const (
+	testAuthorizedKey     = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQDFCRsiFgCqEcNRwDnfzU7ex5MLJ4+3aD6ZpitGQ4/shJ5gLowJXs1wnjs16IrcDcbglHu474YiwHYky9TDoUn6YhLh/XbYCzRq48o010JKlDPwKRolaHmD/xeeG/KJISsq2LDlLdIRkmCURy93P6rHpxSTjGT3y09ORNNuwnbsw++cis45CnAehqZYv+gDCUda0UC1ixsLIWsDKMHcvJ9nrHL6po9vH4c7AAc91l9sO3qttTdlLotPnS3tOPO0rYtCDYQWqbbZJ3FHAyvR07RPirsORdxu3T5d4ZU/kYYe1X3HYwkF+Tpc+uqB1iTrnHTmmv0DluDj8g/aUbTO+X7R test@example.com"
+	testKeyMD5Fingerprint = "a8:ce:78:ce:9b:97:27:11:03:e6:31:50:78:a0:c8:bd"
+)
+
+func TestMD5Fingerprint(t *testing.T) {
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testAuthorizedKey))
+	if err != nil {
+		t.Fatalf("ssh.ParseAuthorizedKey() returned error: %v", err)
+	}
+
+	if got := md5Fingerprint(pubKey); got != testKeyMD5Fingerprint {
+		t.Errorf("md5Fingerprint() = %q, want %q", got, testKeyMD5Fingerprint)
+	}
+}
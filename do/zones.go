@@ -0,0 +1,81 @@
+package do
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/cloudprovider"
+
+	"github.com/digitalocean/digitalocean-cloud-controller-manager/do/metadata"
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/context"
+)
+
+// zones implements cloudprovider.Zones, surfacing each droplet's DO region
+// as its failure domain/region. DigitalOcean does not expose per-datacenter
+// availability zones within a region, so FailureDomain and Region are both
+// set to the droplet's region slug, mirroring how the GCE cloud provider
+// derives topology.kubernetes.io/region from instance metadata.
+type zones struct {
+	cache    *dropletCache
+	metadata metadata.Client
+}
+
+func newZones(cache *dropletCache) cloudprovider.Zones {
+	return &zones{cache, metadata.NewClient()}
+}
+
+// GetZone returns the Zone containing the current failure zone and locality
+// region that the program is running in. This method is called from the
+// node that DOES run the requested code.
+func (z *zones) GetZone() (cloudprovider.Zone, error) {
+	md, err := z.metadata.Metadata()
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	return z.GetZoneByProviderID(strconv.Itoa(md.DropletID))
+}
+
+// GetZoneByProviderID returns the Zone containing the current zone and
+// locality region of the node specified by providerId. This method is
+// particularly used in the context of external cloud providers where node
+// initialization must be done outside the kubelet.
+func (z *zones) GetZoneByProviderID(providerID string) (cloudprovider.Zone, error) {
+	id, err := dropletIDFromProviderID(providerID)
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	droplet, err := z.cache.byID(context.TODO(), id)
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	return zoneForDroplet(droplet), nil
+}
+
+// GetZoneByNodeName returns the Zone containing the current zone and
+// locality region of the node specified by node name. This method is
+// particularly used in the context of external cloud providers where node
+// initialization must be done outside the kubelet.
+func (z *zones) GetZoneByNodeName(nodeName types.NodeName) (cloudprovider.Zone, error) {
+	droplet, err := z.cache.byName(context.TODO(), string(nodeName))
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	return zoneForDroplet(droplet), nil
+}
+
+// zoneForDroplet builds a cloudprovider.Zone from a droplet's region. DO has
+// no concept of per-region failure zones, so FailureDomain and Region are
+// both populated with the region slug (e.g. "nyc1"); the node controller
+// uses these to set the topology.kubernetes.io/region and
+// failure-domain.beta.kubernetes.io/region node labels.
+func zoneForDroplet(droplet *godo.Droplet) cloudprovider.Zone {
+	return cloudprovider.Zone{
+		FailureDomain: droplet.Region.Slug,
+		Region:        droplet.Region.Slug,
+	}
+}